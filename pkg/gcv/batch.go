@@ -0,0 +1,126 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcv
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/config-validator/pkg/api/validator"
+)
+
+// AssetResult is the outcome of reviewing a single asset as part of a batch. Violations is
+// always non-nil so JSON marshaling of a batch produces "[]" rather than "null" for
+// assets with no violations.
+type AssetResult struct {
+	Asset      *validator.Asset
+	Violations []*validator.Violation
+	Err        error
+}
+
+// reviewOptions holds the functional options for a batch review call.
+type reviewOptions struct {
+	concurrency int
+}
+
+// ReviewOption configures a batch review call.
+type ReviewOption func(*reviewOptions)
+
+// WithConcurrency sets the number of workers used to process a batch review. It defaults to
+// runtime.GOMAXPROCS(0).
+func WithConcurrency(n int) ReviewOption {
+	return func(o *reviewOptions) {
+		o.concurrency = n
+	}
+}
+
+func newReviewOptions(opts []ReviewOption) *reviewOptions {
+	options := &reviewOptions{concurrency: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.concurrency < 1 {
+		options.concurrency = 1
+	}
+	return options
+}
+
+// ReviewAssets reviews a batch of assets concurrently, using a worker pool bounded by
+// WithConcurrency (defaulting to GOMAXPROCS). Results are returned in the same order as
+// assets. An error reviewing one asset is captured in its AssetResult.Err rather than
+// aborting the rest of the batch; the returned error is non-nil only if ctx was canceled.
+func (v *Validator) ReviewAssets(ctx context.Context, assets []*validator.Asset, opts ...ReviewOption) ([]*AssetResult, error) {
+	return runBatch(ctx, len(assets), opts, func(ctx context.Context, i int) *AssetResult {
+		violations, err := v.ReviewAsset(ctx, assets[i])
+		if violations == nil {
+			violations = []*validator.Violation{}
+		}
+		return &AssetResult{Asset: assets[i], Violations: violations, Err: err}
+	})
+}
+
+// ReviewUnmarshalledJSONBatch is the concurrent, batch analog of ReviewUnmarshalledJSON.
+// Results are returned in the same order as assets.
+func (v *Validator) ReviewUnmarshalledJSONBatch(ctx context.Context, assets []map[string]interface{}, opts ...ReviewOption) ([]*AssetResult, error) {
+	return runBatch(ctx, len(assets), opts, func(ctx context.Context, i int) *AssetResult {
+		result, err := v.ReviewUnmarshalledJSON(ctx, assets[i])
+		if err != nil {
+			return &AssetResult{Violations: []*validator.Violation{}, Err: err}
+		}
+		violations, err := result.ToViolations()
+		if violations == nil {
+			violations = []*validator.Violation{}
+		}
+		return &AssetResult{Violations: violations, Err: err}
+	})
+}
+
+// runBatch fans work out across a bounded worker pool, preserving input order in the result
+// slice and stopping early (with a non-nil error) if ctx is canceled.
+func runBatch(ctx context.Context, n int, opts []ReviewOption, work func(ctx context.Context, i int) *AssetResult) ([]*AssetResult, error) {
+	options := newReviewOptions(opts)
+	results := make([]*AssetResult, n)
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	workers := options.concurrency
+	if workers > n {
+		workers = n
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = work(ctx, i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			close(indices)
+			wg.Wait()
+			return results, ctx.Err()
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	return results, ctx.Err()
+}
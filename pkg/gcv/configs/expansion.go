@@ -0,0 +1,124 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ExpansionTemplateKind is the "kind" used by YAML documents describing an ExpansionTemplate.
+const ExpansionTemplateKind = "ExpansionTemplate"
+
+// ExpansionTemplate describes how to synthesize a "child" asset from a portion of a
+// "parent" asset so that constraints can be evaluated against resources CAI never
+// exports directly (e.g. the individual GCE instances produced by an instance
+// template). This mirrors Gatekeeper's own ExpansionTemplate concept, adapted to
+// CAI asset shapes instead of admission requests.
+type ExpansionTemplate struct {
+	// ApplyTo is the set of parent asset types this template expands, e.g.
+	// "compute.googleapis.com/InstanceTemplate".
+	ApplyTo []string `json:"applyTo"`
+	// GeneratedAssetType is the asset_type CAI would have used for the synthetic
+	// child, e.g. "compute.googleapis.com/Instance".
+	GeneratedAssetType string `json:"generatedAssetType"`
+	// TemplateSource is a JSONPath into the parent's resource.data identifying the
+	// sub-object to lift into the child's resource.data, e.g.
+	// ".properties.instance_template.properties".
+	TemplateSource string `json:"templateSource"`
+	// EnforcementAction, if set, overrides the enforcementAction of any violation
+	// raised against the generated child.
+	EnforcementAction string `json:"enforcementAction,omitempty"`
+}
+
+// Name returns a human-readable identifier for this template, used in error messages.
+func (e *ExpansionTemplate) Name() string {
+	return fmt.Sprintf("%s->%s", strings.Join(e.ApplyTo, ","), e.GeneratedAssetType)
+}
+
+// expansionTemplateSpec is the on-disk shape of an ExpansionTemplate YAML document.
+type expansionTemplateSpec struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec ExpansionTemplate `json:"spec"`
+}
+
+// LoadExpansionTemplates converts the ExpansionTemplate documents in objs (loaded
+// alongside constraints and constraint templates from policyPaths) into ExpansionTemplates.
+// Documents of any other kind are skipped, mirroring mutation.FromUnstructured.
+func LoadExpansionTemplates(objs []*unstructured.Unstructured) ([]*ExpansionTemplate, error) {
+	var templates []*ExpansionTemplate
+	for _, u := range objs {
+		template, ok, err := loadExpansionTemplate(u)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			templates = append(templates, template)
+		}
+	}
+	return templates, nil
+}
+
+// loadExpansionTemplate converts an *unstructured.Unstructured loaded from policyPaths
+// into an *ExpansionTemplate, or returns ok=false if u is not an ExpansionTemplate.
+func loadExpansionTemplate(u *unstructured.Unstructured) (template *ExpansionTemplate, ok bool, err error) {
+	if u.GetKind() != ExpansionTemplateKind {
+		return nil, false, nil
+	}
+
+	spec, found, err := unstructured.NestedMap(u.Object, "spec")
+	if err != nil {
+		return nil, true, fmt.Errorf("fetching spec for ExpansionTemplate %s: %w", u.GetName(), err)
+	}
+	if !found {
+		return nil, true, fmt.Errorf("ExpansionTemplate %s missing spec", u.GetName())
+	}
+
+	applyTo, _, err := unstructured.NestedStringSlice(spec, "applyTo")
+	if err != nil {
+		return nil, true, fmt.Errorf("ExpansionTemplate %s has invalid applyTo: %w", u.GetName(), err)
+	}
+	if len(applyTo) == 0 {
+		return nil, true, fmt.Errorf("ExpansionTemplate %s must set spec.applyTo", u.GetName())
+	}
+
+	generatedAssetType, _, err := unstructured.NestedString(spec, "generatedAssetType")
+	if err != nil || generatedAssetType == "" {
+		return nil, true, fmt.Errorf("ExpansionTemplate %s must set spec.generatedAssetType", u.GetName())
+	}
+
+	templateSource, _, err := unstructured.NestedString(spec, "templateSource")
+	if err != nil || templateSource == "" {
+		return nil, true, fmt.Errorf("ExpansionTemplate %s must set spec.templateSource", u.GetName())
+	}
+
+	enforcementAction, _, err := unstructured.NestedString(spec, "enforcementAction")
+	if err != nil {
+		return nil, true, fmt.Errorf("ExpansionTemplate %s has invalid enforcementAction: %w", u.GetName(), err)
+	}
+
+	return &ExpansionTemplate{
+		ApplyTo:            applyTo,
+		GeneratedAssetType: generatedAssetType,
+		TemplateSource:     templateSource,
+		EnforcementAction:  enforcementAction,
+	}, true, nil
+}
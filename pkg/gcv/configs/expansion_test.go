@@ -0,0 +1,54 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestLoadExpansionTemplates(t *testing.T) {
+	expansion := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1alpha1",
+		"kind":       ExpansionTemplateKind,
+		"metadata":   map[string]interface{}{"name": "instance-template-expansion"},
+		"spec": map[string]interface{}{
+			"applyTo":            []interface{}{"compute.googleapis.com/InstanceTemplate"},
+			"generatedAssetType": "compute.googleapis.com/Instance",
+			"templateSource":     ".properties.instance_template.properties",
+			"enforcementAction":  "dryrun",
+		},
+	}}
+	constraint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "GCPAlwaysViolatesConstraintV1",
+		"metadata": map[string]interface{}{"name": "not-an-expansion"},
+	}}
+
+	templates, err := LoadExpansionTemplates([]*unstructured.Unstructured{constraint, expansion})
+	if err != nil {
+		t.Fatalf("LoadExpansionTemplates returned error: %v", err)
+	}
+	if len(templates) != 1 {
+		t.Fatalf("expected exactly one ExpansionTemplate to be loaded, got %d", len(templates))
+	}
+	got := templates[0]
+	if got.GeneratedAssetType != "compute.googleapis.com/Instance" {
+		t.Errorf("unexpected GeneratedAssetType: %v", got.GeneratedAssetType)
+	}
+	if got.EnforcementAction != "dryrun" {
+		t.Errorf("unexpected EnforcementAction: %v", got.EnforcementAction)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// splitRepoTag splits a "registry[:port]/repository[:tag]" reference into the repository
+// name and tag, splitting on the last colon that comes after the last slash so that a
+// port in the registry host (e.g. "localhost:5000/myrepo:v1") isn't mistaken for the tag
+// separator.
+func splitRepoTag(ref string) (name, tag string) {
+	slash := strings.LastIndex(ref, "/")
+	colon := strings.LastIndex(ref, ":")
+	if colon <= slash {
+		return ref, ""
+	}
+	return ref[:colon], ref[colon+1:]
+}
+
+// newRemoteRepository opens repo (a "registry/repository[:tag]" reference) using the
+// standard docker credential helper chain for authentication.
+func newRemoteRepository(ref string) (*remote.Repository, error) {
+	name, _ := splitRepoTag(ref)
+	repo, err := remote.NewRepository(name)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository %s: %w", name, err)
+	}
+
+	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("loading docker credentials: %w", err)
+	}
+	repo.Client = &auth.Client{
+		Client:     nil,
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(store),
+	}
+	return repo, nil
+}
+
+// resolveOCIDigest resolves ref's tag to the manifest digest it currently points at, so
+// that unpinned references still get a stable cache key.
+func resolveOCIDigest(ctx context.Context, ref string) (string, error) {
+	repo, err := newRemoteRepository(ref)
+	if err != nil {
+		return "", err
+	}
+	_, tag := splitRepoTag(ref)
+	if tag == "" {
+		tag = "latest"
+	}
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return "", fmt.Errorf("resolving tag %s: %w", tag, err)
+	}
+	return strings.TrimPrefix(string(desc.Digest), "sha256:"), nil
+}
+
+// pullOCIBundle copies every layer of ref (expected to be the single-layer tarball of
+// constraints/templates/lib produced by `oras push`) into dest and unpacks it.
+func pullOCIBundle(ctx context.Context, ref, digest, dest string) error {
+	repo, err := newRemoteRepository(ref)
+	if err != nil {
+		return err
+	}
+
+	fetchDir, err := os.MkdirTemp("", "config-validator-oci-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(fetchDir)
+
+	store, err := file.New(fetchDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	target := "sha256:" + digest
+	desc, err := oras.Copy(ctx, repo, target, store, target, oras.DefaultCopyOptions)
+	if err != nil {
+		return fmt.Errorf("copying %s: %w", target, err)
+	}
+	_ = desc
+
+	return filepath.Walk(fetchDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if !strings.HasSuffix(path, ".tar.gz") && !strings.HasSuffix(path, ".tgz") {
+			return nil
+		}
+		return extractTarGz(path, dest)
+	})
+}
+
+// extractTarGz unpacks the gzip-compressed tar archive at src into dest.
+func extractTarGz(src, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, filepath.Clean(header.Name))
+		if target != filepath.Clean(dest) && !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %q escapes destination directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			out.Close()
+		}
+	}
+}
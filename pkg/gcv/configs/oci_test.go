@@ -0,0 +1,39 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import "testing"
+
+func TestSplitRepoTag(t *testing.T) {
+	tests := []struct {
+		ref      string
+		wantName string
+		wantTag  string
+	}{
+		{"gcr.io/my-project/policies:v1", "gcr.io/my-project/policies", "v1"},
+		{"gcr.io/my-project/policies", "gcr.io/my-project/policies", ""},
+		{"localhost:5000/myrepo:v1", "localhost:5000/myrepo", "v1"},
+		{"localhost:5000/myrepo", "localhost:5000/myrepo", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ref, func(t *testing.T) {
+			name, tag := splitRepoTag(tc.ref)
+			if name != tc.wantName || tag != tc.wantTag {
+				t.Errorf("splitRepoTag(%q) = (%q, %q), want (%q, %q)", tc.ref, name, tag, tc.wantName, tc.wantTag)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		name       string
+		entry      string
+		wantType   string
+		wantRef    string
+		wantDigest string
+	}{
+		{
+			name:     "local path",
+			entry:    "policies/constraints",
+			wantType: "*configs.FileSource",
+			wantRef:  "policies/constraints",
+		},
+		{
+			name:     "oci without digest",
+			entry:    "oci://gcr.io/my-project/policies:v1",
+			wantType: "*configs.OCISource",
+			wantRef:  "gcr.io/my-project/policies:v1",
+		},
+		{
+			name:       "oci with pinned digest",
+			entry:      "oci://gcr.io/my-project/policies:v1@sha256:abc123",
+			wantType:   "*configs.OCISource",
+			wantRef:    "gcr.io/my-project/policies:v1",
+			wantDigest: "abc123",
+		},
+		{
+			name:     "https bundle",
+			entry:    "https://example.com/bundle.tar.gz",
+			wantType: "*configs.HTTPSource",
+			wantRef:  "https://example.com/bundle.tar.gz",
+		},
+		{
+			name:       "https bundle with pinned digest",
+			entry:      "https://example.com/bundle.tar.gz#sha256=abc123",
+			wantType:   "*configs.HTTPSource",
+			wantRef:    "https://example.com/bundle.tar.gz",
+			wantDigest: "abc123",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := ParseSource(tc.entry)
+			if err != nil {
+				t.Fatalf("ParseSource(%q) returned error: %v", tc.entry, err)
+			}
+			switch s := source.(type) {
+			case *FileSource:
+				if s.Path != tc.wantRef {
+					t.Errorf("FileSource.Path = %q, want %q", s.Path, tc.wantRef)
+				}
+			case *OCISource:
+				if s.Ref != tc.wantRef {
+					t.Errorf("OCISource.Ref = %q, want %q", s.Ref, tc.wantRef)
+				}
+				if s.Digest != tc.wantDigest {
+					t.Errorf("OCISource.Digest = %q, want %q", s.Digest, tc.wantDigest)
+				}
+			case *HTTPSource:
+				if s.URL != tc.wantRef {
+					t.Errorf("HTTPSource.URL = %q, want %q", s.URL, tc.wantRef)
+				}
+				if s.Digest != tc.wantDigest {
+					t.Errorf("HTTPSource.Digest = %q, want %q", s.Digest, tc.wantDigest)
+				}
+			default:
+				t.Fatalf("ParseSource(%q) returned unexpected type %T", tc.entry, source)
+			}
+		})
+	}
+}
+
+func TestFileSource_FetchIsIdentity(t *testing.T) {
+	f := &FileSource{Path: "policies/constraints"}
+	got, err := f.Fetch(context.Background(), "/tmp/cache")
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if got != "policies/constraints" {
+		t.Errorf("Fetch() = %q, want %q", got, "policies/constraints")
+	}
+}
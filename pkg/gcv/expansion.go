@@ -0,0 +1,101 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/config-validator/pkg/gcv/configs"
+)
+
+// expandAsset builds the synthetic child asset described by expansion from asset. matched is
+// false (with a nil child and error) if asset's type doesn't appear in expansion.ApplyTo.
+func expandAsset(asset map[string]interface{}, expansion *configs.ExpansionTemplate) (child map[string]interface{}, matched bool, err error) {
+	assetType, _ := asset[assetTypeKey].(string)
+	if !stringInSlice(expansion.ApplyTo, assetType) {
+		return nil, false, nil
+	}
+
+	resourceData, _, err := lookupJSONPath(asset, resourceKey+"."+resourceDataKey)
+	if err != nil {
+		return nil, true, fmt.Errorf("resolving %s.%s: %w", resourceKey, resourceDataKey, err)
+	}
+	resourceDataMap, ok := resourceData.(map[string]interface{})
+	if !ok {
+		return nil, true, fmt.Errorf("asset %v has no %s.%s object to expand from", asset["name"], resourceKey, resourceDataKey)
+	}
+
+	source, found, err := lookupJSONPath(resourceDataMap, expansion.TemplateSource)
+	if err != nil {
+		return nil, true, fmt.Errorf("resolving templateSource %q: %w", expansion.TemplateSource, err)
+	}
+	if !found {
+		return nil, true, fmt.Errorf("templateSource %q not found on asset %v", expansion.TemplateSource, asset["name"])
+	}
+	sourceMap, ok := source.(map[string]interface{})
+	if !ok {
+		return nil, true, fmt.Errorf("templateSource %q does not resolve to an object", expansion.TemplateSource)
+	}
+
+	name, _ := asset["name"].(string)
+	child = map[string]interface{}{
+		"name":       fmt.Sprintf("%s/expanded/%s", name, expansion.GeneratedAssetType),
+		assetTypeKey: expansion.GeneratedAssetType,
+		resourceKey: map[string]interface{}{
+			resourceDataKey: sourceMap,
+		},
+	}
+	if ancestry, ok := asset[ancestryPathKey]; ok {
+		child[ancestryPathKey] = ancestry
+	}
+	if ancestors, ok := asset[ancestorSliceKey]; ok {
+		child[ancestorSliceKey] = ancestors
+	}
+
+	return child, true, nil
+}
+
+// lookupJSONPath resolves a dot-separated JSONPath such as
+// ".properties.instance_template.properties" against obj. The leading "." is optional.
+func lookupJSONPath(obj map[string]interface{}, path string) (interface{}, bool, error) {
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return obj, true, nil
+	}
+
+	var cur interface{} = obj
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("cannot descend into %q: not an object", part)
+		}
+		next, found := m[part]
+		if !found {
+			return nil, false, nil
+		}
+		cur = next
+	}
+	return cur, true, nil
+}
+
+func stringInSlice(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcv
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/config-validator/pkg/gcv/configs"
+)
+
+func TestExpandAsset_InstanceTemplateToInstance(t *testing.T) {
+	expansion := &configs.ExpansionTemplate{
+		ApplyTo:            []string{"compute.googleapis.com/InstanceTemplate"},
+		GeneratedAssetType: "compute.googleapis.com/Instance",
+		TemplateSource:     ".properties.instance_template.properties",
+	}
+
+	asset := map[string]interface{}{
+		"name":          "//compute.googleapis.com/projects/p/global/instanceTemplates/my-template",
+		"asset_type":    "compute.googleapis.com/InstanceTemplate",
+		"ancestry_path": "organization/1/project/p",
+		"resource": map[string]interface{}{
+			"data": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"instance_template": map[string]interface{}{
+						"properties": map[string]interface{}{
+							"machineType": "n1-standard-1",
+							"disks":       []interface{}{"disk-1"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	child, matched, err := expandAsset(asset, expansion)
+	if err != nil {
+		t.Fatalf("expandAsset returned error: %v", err)
+	}
+	if !matched {
+		t.Fatalf("expected expansion to match InstanceTemplate asset")
+	}
+	if child["asset_type"] != "compute.googleapis.com/Instance" {
+		t.Errorf("unexpected generated asset_type: %v", child["asset_type"])
+	}
+	if child["ancestry_path"] != "organization/1/project/p" {
+		t.Errorf("expected child to inherit parent ancestry_path, got %v", child["ancestry_path"])
+	}
+
+	data, ok := child["resource"].(map[string]interface{})["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected child resource.data to be an object, got %v", child["resource"])
+	}
+	if data["machineType"] != "n1-standard-1" {
+		t.Errorf("expected lifted machineType, got %v", data["machineType"])
+	}
+}
+
+func TestExpandAsset_NoMatch(t *testing.T) {
+	expansion := &configs.ExpansionTemplate{
+		ApplyTo:            []string{"compute.googleapis.com/InstanceTemplate"},
+		GeneratedAssetType: "compute.googleapis.com/Instance",
+		TemplateSource:     ".properties.instance_template.properties",
+	}
+
+	asset := map[string]interface{}{
+		"name":       "//storage.googleapis.com/my-bucket",
+		"asset_type": "storage.googleapis.com/Bucket",
+	}
+
+	_, matched, err := expandAsset(asset, expansion)
+	if err != nil {
+		t.Fatalf("expandAsset returned error: %v", err)
+	}
+	if matched {
+		t.Errorf("expected no match for unrelated asset type")
+	}
+}
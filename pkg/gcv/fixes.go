@@ -0,0 +1,118 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/config-validator/pkg/api/validator"
+	asset2 "github.com/GoogleCloudPlatform/config-validator/pkg/asset"
+	"github.com/GoogleCloudPlatform/config-validator/pkg/mutation"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// remediationsByConstraint scans constraints for a `spec.remediation` stanza (a list of
+// mutator names) and returns the constraint-name -> mutator-IDs mapping ReviewAssetWithFixes
+// uses to find the fix for a violation.
+func remediationsByConstraint(constraints []*unstructured.Unstructured) map[string][]string {
+	remediations := make(map[string][]string, len(constraints))
+	for _, c := range constraints {
+		mutatorIDs, _, _ := unstructured.NestedStringSlice(c.Object, "spec", "remediation")
+		if len(mutatorIDs) > 0 {
+			remediations[c.GetName()] = mutatorIDs
+		}
+	}
+	return remediations
+}
+
+// ReviewAssetWithFixes reviews asset as ReviewAsset does, then for every violation whose
+// constraint has a `remediation:` stanza, computes the JSON Patch its linked mutators
+// would apply to fix it. It dry-runs each patch set by re-applying constraints to the
+// patched asset and logs (but does not fail on) any violation that survives the fix, since
+// the patches are advisory only: Validator never mutates the caller's input.
+func (v *Validator) ReviewAssetWithFixes(ctx context.Context, asset *validator.Asset) (*Result, []mutation.JSONPatch, error) {
+	if err := asset2.SanitizeAncestryPath(asset); err != nil {
+		return nil, nil, err
+	}
+	if err := asset2.ValidateAsset(asset); err != nil {
+		return nil, nil, err
+	}
+	assetInterface, err := asset2.ConvertResourceViaJSONToInterface(asset)
+	if err != nil {
+		return nil, nil, err
+	}
+	assetMap := assetInterface.(map[string]interface{})
+
+	result, err := v.ReviewUnmarshalledJSON(ctx, assetMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v.clientMu.RLock()
+	remediations, mutators := v.remediations, v.mutators
+	v.clientMu.RUnlock()
+
+	var patches []mutation.JSONPatch
+	for _, violation := range result.Violations {
+		mutatorIDs := remediations[violation.Constraint]
+		for _, id := range mutatorIDs {
+			m, found := mutators[id]
+			if !found {
+				return nil, nil, fmt.Errorf("constraint %s references unknown mutator %q", violation.Constraint, id)
+			}
+			matched, err := m.Matches(assetMap)
+			if err != nil {
+				return nil, nil, fmt.Errorf("mutator %s: %w", id, err)
+			}
+			if !matched {
+				continue
+			}
+			p, err := m.Patch(assetMap)
+			if err != nil {
+				return nil, nil, fmt.Errorf("mutator %s: %w", id, err)
+			}
+			patches = append(patches, p...)
+		}
+	}
+
+	if len(patches) > 0 {
+		remaining, err := v.dryRunRemainingViolations(ctx, assetMap, patches)
+		if err != nil {
+			return nil, nil, fmt.Errorf("verifying suggested fix for %v: %w", assetMap["name"], err)
+		}
+		result.RemainingViolationsAfterFix = remaining
+		if len(remaining) > 0 {
+			glog.V(logRequestsVerboseLevel).Infof("suggested fix for %v leaves %d violation(s) unresolved", assetMap["name"], len(remaining))
+		}
+	}
+
+	return result, patches, nil
+}
+
+// dryRunRemainingViolations applies patches to a copy of asset in memory and re-runs
+// constraint evaluation against it, returning whatever violations remain.
+func (v *Validator) dryRunRemainingViolations(ctx context.Context, asset map[string]interface{}, patches []mutation.JSONPatch) ([]*validator.Violation, error) {
+	fixed, err := mutation.ApplyPatches(asset, patches)
+	if err != nil {
+		return nil, fmt.Errorf("applying suggested patches: %w", err)
+	}
+	result, err := v.ReviewUnmarshalledJSON(ctx, fixed)
+	if err != nil {
+		return nil, err
+	}
+	return result.Violations, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcv
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRemediationsByConstraint(t *testing.T) {
+	withRemediation := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "require-encryption"},
+		"spec": map[string]interface{}{
+			"remediation": []interface{}{"assign-encryption"},
+		},
+	}}
+	withoutRemediation := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "no-fix-available"},
+		"spec":     map[string]interface{}{},
+	}}
+
+	got := remediationsByConstraint([]*unstructured.Unstructured{withRemediation, withoutRemediation})
+
+	if len(got) != 1 {
+		t.Fatalf("expected exactly one constraint with a remediation, got %d: %v", len(got), got)
+	}
+	mutatorIDs, ok := got["require-encryption"]
+	if !ok || len(mutatorIDs) != 1 || mutatorIDs[0] != "assign-encryption" {
+		t.Errorf("unexpected remediation mapping for require-encryption: %v", mutatorIDs)
+	}
+	if _, ok := got["no-fix-available"]; ok {
+		t.Errorf("constraint without a remediation stanza should not appear in the map")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcv
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/config-validator/pkg/api/validator"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/types"
+)
+
+// Result is the result of reviewing a single resource against a single target.
+type Result struct {
+	// TargetName is the Constraint Framework target handler this resource was reviewed under.
+	TargetName string
+	// Name is the name of the resource that was reviewed.
+	Name string
+	// Asset is the original, unconverted resource that was reviewed.
+	Asset interface{}
+	// Resource is the target-specific representation of the resource that was reviewed.
+	Resource interface{}
+	// Violations holds the constraint violations raised for this resource.
+	Violations []*validator.Violation
+	// ExpandedFrom is the name of the parent resource that produced this result via an
+	// ExpansionTemplate, or empty if this result was produced directly from CAI/TF input.
+	ExpandedFrom string
+	// RemainingViolationsAfterFix holds whatever violations survived applying the suggested
+	// fix patches during ReviewAssetWithFixes's dry-run verification. It is nil unless that
+	// method was used and produced at least one patch.
+	RemainingViolationsAfterFix []*validator.Violation
+}
+
+// NewResult builds a Result from the responses returned by the Constraint Framework client.
+func NewResult(targetName, name string, asset, resource interface{}, responses *types.Responses) (*Result, error) {
+	result := &Result{TargetName: targetName, Name: name, Asset: asset, Resource: resource}
+	if responses == nil {
+		return result, nil
+	}
+
+	for _, r := range responses.Results() {
+		violation, err := violationFromResult(r)
+		if err != nil {
+			return nil, fmt.Errorf("converting constraint result to violation: %w", err)
+		}
+		result.Violations = append(result.Violations, violation)
+	}
+
+	return result, nil
+}
+
+// ToViolations returns the violations contained in this Result.
+func (r *Result) ToViolations() ([]*validator.Violation, error) {
+	return r.Violations, nil
+}
+
+// violationFromResult converts a single Constraint Framework result into a validator.Violation.
+func violationFromResult(result *types.Result) (*validator.Violation, error) {
+	return &validator.Violation{
+		Constraint: result.Constraint.GetName(),
+		Message:    result.Msg,
+		Metadata:   result.Metadata,
+	}, nil
+}
@@ -19,12 +19,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/GoogleCloudPlatform/config-validator/pkg/api/validator"
 	asset2 "github.com/GoogleCloudPlatform/config-validator/pkg/asset"
 	"github.com/GoogleCloudPlatform/config-validator/pkg/gcptarget"
 	"github.com/GoogleCloudPlatform/config-validator/pkg/gcv/configs"
 	"github.com/GoogleCloudPlatform/config-validator/pkg/multierror"
+	"github.com/GoogleCloudPlatform/config-validator/pkg/mutation"
 	"github.com/GoogleCloudPlatform/config-validator/pkg/tftarget"
 	"github.com/golang/glog"
 	cfclient "github.com/open-policy-agent/frameworks/constraint/pkg/client"
@@ -41,6 +44,12 @@ const (
 	ancestryPathKey = "ancestry_path"
 	// The JSON object key for ancestors list
 	ancestorSliceKey = "ancestors"
+	// The JSON object key for the asset type
+	assetTypeKey = "asset_type"
+	// The JSON object key for the resource data on an asset
+	resourceKey = "resource"
+	// The JSON object key for the resource data nested under "resource"
+	resourceDataKey = "data"
 )
 
 type ConfigValidator interface {
@@ -70,12 +79,27 @@ type Validator struct {
 	gcpCFClient      *cfclient.Client
 	k8sCFClient      *cfclient.Client
 	tfCFClient       *cfclient.Client
+	// expansions are the ExpansionTemplates loaded from policyPaths that describe how to
+	// synthesize child assets from parent assets before constraint evaluation.
+	expansions []*configs.ExpansionTemplate
+	// mutators are the GCPAssign/GCPAssignMetadata mutators loaded from policyPaths, keyed
+	// by Mutator.ID().
+	mutators map[string]mutation.Mutator
+	// remediations maps a constraint's name to the IDs of the mutators listed in its
+	// `remediation:` stanza, used by ReviewAssetWithFixes to find the fix for a violation.
+	remediations map[string][]string
+	// clientMu guards the CF client fields and expansions above. Review calls only need a
+	// read lock, since the local driver's query engine is safe for concurrent reads; it is
+	// held for writing only when the clients/expansions themselves are replaced (e.g.
+	// ReloadFromConfig).
+	clientMu sync.RWMutex
 }
 
 // Stores functional options for CF client
 type initOptions struct {
-	driverArgs []local.Arg
-	clientArgs []cfclient.Opt
+	driverArgs     []local.Arg
+	clientArgs     []cfclient.Opt
+	bundleCacheDir string
 }
 
 type Option = func(*initOptions)
@@ -86,18 +110,41 @@ func DisableBuiltins(builtins ...string) Option {
 	}
 }
 
+// WithBundleCacheDir sets the directory used to persistently cache OCI- and HTTP-sourced
+// policy bundles across invocations, so a long-running validator doesn't re-download a
+// bundle it already has on disk. If unset, NewValidator creates a fresh temporary directory
+// on every call.
+func WithBundleCacheDir(path string) Option {
+	return func(o *initOptions) {
+		o.bundleCacheDir = path
+	}
+}
+
 // NewValidatorConfig returns a new ValidatorConfig.
 // By default it will initialize the underlying query evaluation engine by loading supporting library, constraints, and constraint templates.
 // We may want to make this initialization behavior configurable in the future.
-func NewValidatorConfig(policyPaths []string, policyLibraryPath string) (*configs.Configuration, error) {
+//
+// policyPaths and policyLibraryPath entries may be local filesystem paths (the default),
+// "oci://registry/repo:tag[@sha256:...]" OCI bundle references, or "https://.../bundle.tar.gz"
+// HTTP(S) bundle URLs; non-local entries are fetched into bundleCacheDir before loading.
+func NewValidatorConfig(policyPaths []string, policyLibraryPath string, bundleCacheDir string) (*configs.Configuration, error) {
 	if len(policyPaths) == 0 {
 		return nil, fmt.Errorf("No policy path set, provide an option to set the policy path gcv.PolicyPath")
 	}
 	if policyLibraryPath == "" {
 		return nil, fmt.Errorf("No policy library set")
 	}
-	glog.V(logRequestsVerboseLevel).Infof("loading policy dir: %v lib dir: %s", policyPaths, policyLibraryPath)
-	return configs.NewConfiguration(policyPaths, policyLibraryPath)
+	ctx := context.Background()
+	resolvedPaths, err := configs.ResolvePaths(ctx, policyPaths, bundleCacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving policyPaths: %w", err)
+	}
+	resolvedLibPaths, err := configs.ResolvePaths(ctx, []string{policyLibraryPath}, bundleCacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving policyLibraryPath: %w", err)
+	}
+	glog.V(logRequestsVerboseLevel).Infof("loading policy dir: %v lib dir: %s", resolvedPaths, resolvedLibPaths[0])
+	return configs.NewConfiguration(resolvedPaths, resolvedLibPaths[0])
 }
 
 func newCFClient(
@@ -166,10 +213,27 @@ func NewValidatorFromConfig(config *configs.Configuration, opts ...Option) (*Val
 		return nil, fmt.Errorf("unable to set up TF Constraint Framework client: %w", err)
 	}
 
+	mutators, err := mutation.FromUnstructured(config.Mutators)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load mutators: %w", err)
+	}
+	mutatorsByID := make(map[string]mutation.Mutator, len(mutators))
+	for _, m := range mutators {
+		mutatorsByID[m.ID()] = m
+	}
+
+	expansions, err := configs.LoadExpansionTemplates(config.Expansions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load expansion templates: %w", err)
+	}
+
 	ret := &Validator{
-		gcpCFClient: gcpCFClient,
-		k8sCFClient: k8sCFClient,
-		tfCFClient:  tfCFClient,
+		gcpCFClient:  gcpCFClient,
+		k8sCFClient:  k8sCFClient,
+		tfCFClient:   tfCFClient,
+		expansions:   expansions,
+		mutators:     mutatorsByID,
+		remediations: remediationsByConstraint(config.GCPConstraints),
 	}
 	return ret, nil
 }
@@ -178,13 +242,71 @@ func NewValidatorFromConfig(config *configs.Configuration, opts ...Option) (*Val
 // By default it will initialize the underlying query evaluation engine by loading supporting library, constraints, and constraint templates.
 // We may want to make this initialization behavior configurable in the future.
 func NewValidator(policyPaths []string, policyLibraryPath string, opts ...Option) (*Validator, error) {
-	config, err := NewValidatorConfig(policyPaths, policyLibraryPath)
+	options := &initOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	bundleCacheDir := options.bundleCacheDir
+	if bundleCacheDir == "" {
+		dir, err := os.MkdirTemp("", "config-validator-bundle-cache-*")
+		if err != nil {
+			return nil, fmt.Errorf("creating default bundle cache dir: %w", err)
+		}
+		bundleCacheDir = dir
+	}
+
+	config, err := NewValidatorConfig(policyPaths, policyLibraryPath, bundleCacheDir)
 	if err != nil {
 		return nil, err
 	}
 	return NewValidatorFromConfig(config, opts...)
 }
 
+// ReloadFromConfig atomically swaps the GCP, K8S, and TF Constraint Framework clients
+// underlying v for ones built from config, so a long-running validator can pick up new
+// policy (e.g. a refreshed OCI/HTTP bundle) without a redeploy. The clients, expansions,
+// mutators and remediations are replaced together under the write lock, so each single
+// Constraint Framework review call sees either all old or all new state. A review that
+// makes several such calls (an asset with expanded children, or ReviewAssetWithFixes) takes
+// the read lock separately for each, so a reload in between can run its later calls
+// against the new state.
+func (v *Validator) ReloadFromConfig(config *configs.Configuration, opts ...Option) error {
+	gcpCFClient, err := newCFClient(gcptarget.New(), config.GCPTemplates, config.GCPConstraints, opts...)
+	if err != nil {
+		return fmt.Errorf("unable to set up GCP Constraint Framework client: %w", err)
+	}
+	k8sCFClient, err := newCFClient(&k8starget.K8sValidationTarget{}, config.K8STemplates, config.K8SConstraints, opts...)
+	if err != nil {
+		return fmt.Errorf("unable to set up K8S Constraint Framework client: %w", err)
+	}
+	tfCFClient, err := newCFClient(tftarget.New(), config.TFTemplates, config.TFConstraints, opts...)
+	if err != nil {
+		return fmt.Errorf("unable to set up TF Constraint Framework client: %w", err)
+	}
+	mutators, err := mutation.FromUnstructured(config.Mutators)
+	if err != nil {
+		return fmt.Errorf("unable to load mutators: %w", err)
+	}
+	mutatorsByID := make(map[string]mutation.Mutator, len(mutators))
+	for _, m := range mutators {
+		mutatorsByID[m.ID()] = m
+	}
+	expansions, err := configs.LoadExpansionTemplates(config.Expansions)
+	if err != nil {
+		return fmt.Errorf("unable to load expansion templates: %w", err)
+	}
+
+	v.clientMu.Lock()
+	defer v.clientMu.Unlock()
+	v.gcpCFClient = gcpCFClient
+	v.k8sCFClient = k8sCFClient
+	v.tfCFClient = tfCFClient
+	v.expansions = expansions
+	v.mutators = mutatorsByID
+	v.remediations = remediationsByConstraint(config.GCPConstraints)
+	return nil
+}
+
 // NewValidatorFromContents returns a new Validator built from the provided contents of the policy constraints and policy library.
 // This provides a way to create a validator directly from contents instead of reading from the file system.
 // policyLibrary is a slice of file contents of all policy library files.
@@ -241,7 +363,9 @@ func (v *Validator) ReviewTFResourceChange(ctx context.Context, inputResource ma
 	if !handled {
 		return nil, fmt.Errorf("Unhandled resource: %w", err)
 	}
+	v.clientMu.RLock()
 	responses, err := v.tfCFClient.Review(ctx, inputResource)
+	v.clientMu.RUnlock()
 	if err != nil {
 		return nil, fmt.Errorf("TF target Constraint Framework review call failed: %w", err)
 	}
@@ -297,18 +421,102 @@ func (v *Validator) reviewK8SResource(ctx context.Context, asset map[string]inte
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert asset to admission request: %w", err)
 	}
+	v.clientMu.RLock()
 	responses, err := v.k8sCFClient.Review(ctx, k8sResource)
+	v.clientMu.RUnlock()
 	if err != nil {
 		return nil, fmt.Errorf("K8S target Constraint Framework review call failed: %w", err)
 	}
 	return NewResult(configs.K8STargetName, asset["name"].(string), asset, k8sResource.Object, responses)
 }
 
-// reviewGCPResource will pass CAI assets to the cf client with the GCP target.
+// reviewGCPResource will pass CAI assets to the cf client with the GCP target, then expand
+// the asset via any matching ExpansionTemplates and review the generated children as well.
 func (v *Validator) reviewGCPResource(ctx context.Context, asset map[string]interface{}) (*Result, error) {
+	v.clientMu.RLock()
 	responses, err := v.gcpCFClient.Review(ctx, asset)
+	v.clientMu.RUnlock()
 	if err != nil {
 		return nil, fmt.Errorf("GCP target Constraint Framework review call failed: %w", err)
 	}
-	return NewResult(gcptarget.Name, asset["name"].(string), asset, asset, responses)
+	result, err := NewResult(gcptarget.Name, asset["name"].(string), asset, asset, responses)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := v.expand(asset)
+	if err != nil {
+		return nil, fmt.Errorf("expanding asset %s: %w", asset["name"], err)
+	}
+	for _, child := range children {
+		v.clientMu.RLock()
+		childResponses, err := v.gcpCFClient.Review(ctx, child.asset)
+		v.clientMu.RUnlock()
+		if err != nil {
+			return nil, fmt.Errorf("GCP target Constraint Framework review call failed for expanded child of %s: %w", asset["name"], err)
+		}
+		childResult, err := NewResult(gcptarget.Name, child.asset["name"].(string), asset, child.asset, childResponses)
+		if err != nil {
+			return nil, err
+		}
+		childResult.ExpandedFrom = asset["name"].(string)
+		for _, violation := range childResult.Violations {
+			if child.enforcementAction != "" {
+				violation.EnforcementAction = child.enforcementAction
+			}
+			// childResult itself is discarded once its Violations are flattened into
+			// result.Violations below, so stamp ExpandedFrom onto each violation's Metadata
+			// here to keep it observable to callers.
+			if violation.Metadata == nil {
+				violation.Metadata = map[string]interface{}{}
+			}
+			violation.Metadata["expandedFrom"] = childResult.ExpandedFrom
+		}
+		result.Violations = append(result.Violations, childResult.Violations...)
+	}
+
+	return result, nil
+}
+
+// expandedAsset is a synthetic child asset generated by running a parent asset through an
+// ExpansionTemplate, paired with the template that produced it so callers can apply its
+// optional enforcementAction override to the child's violations.
+type expandedAsset struct {
+	asset             map[string]interface{}
+	enforcementAction string
+}
+
+// expand generates the synthetic child assets produced by running asset through every
+// ExpansionTemplate whose applyTo matches asset's type.
+func (v *Validator) expand(asset map[string]interface{}) ([]expandedAsset, error) {
+	v.clientMu.RLock()
+	expansions := v.expansions
+	v.clientMu.RUnlock()
+
+	var children []expandedAsset
+	for _, expansion := range expansions {
+		child, matched, err := expandAsset(asset, expansion)
+		if err != nil {
+			return nil, fmt.Errorf("expansion template %s: %w", expansion.Name(), err)
+		}
+		if matched {
+			children = append(children, expandedAsset{asset: child, enforcementAction: expansion.EnforcementAction})
+		}
+	}
+	return children, nil
+}
+
+// ReviewExpanded returns the synthetic child assets that would be generated for asset by the
+// loaded ExpansionTemplates, without running any constraint evaluation against them. This lets
+// callers preview expansion behavior.
+func (v *Validator) ReviewExpanded(asset map[string]interface{}) ([]map[string]interface{}, error) {
+	children, err := v.expand(asset)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]map[string]interface{}, len(children))
+	for i, child := range children {
+		assets[i] = child.asset
+	}
+	return assets, nil
 }
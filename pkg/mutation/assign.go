@@ -0,0 +1,210 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutation
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// GCPAssignKind is the YAML `kind` for a mutator that sets a literal or
+	// metadata-derived value at a location in an asset's resource.data.
+	GCPAssignKind = "GCPAssign"
+	// GCPAssignMetadataKind is the YAML `kind` for a mutator that sets a literal or
+	// metadata-derived value at a location in the asset's own top-level metadata rather
+	// than in resource.data.
+	GCPAssignMetadataKind = "GCPAssignMetadata"
+)
+
+// Match mirrors the subset of the existing constraint match syntax that makes sense for
+// mutators: which asset types a mutator applies to, optionally narrowed by ancestry.
+type Match struct {
+	// Ancestries, if non-empty, restricts matches to assets whose ancestry_path has one
+	// of these prefixes (e.g. "organization/1/folder/2").
+	Ancestries []string
+}
+
+func (m Match) allows(asset map[string]interface{}) bool {
+	if len(m.Ancestries) == 0 {
+		return true
+	}
+	ancestry, _ := asset["ancestry_path"].(string)
+	for _, prefix := range m.Ancestries {
+		if strings.HasPrefix(ancestry, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignValue is `parameters.assign.value`: either a literal value, or a `fromMetadata`
+// reference naming a key to read out of the asset's ancestry/label metadata.
+type assignValue struct {
+	Literal      interface{}
+	FromMetadata string
+}
+
+func (v assignValue) resolve(asset map[string]interface{}) (interface{}, error) {
+	if v.FromMetadata == "" {
+		return v.Literal, nil
+	}
+	value, found := asset[v.FromMetadata]
+	if !found {
+		return nil, fmt.Errorf("fromMetadata key %q not found on asset", v.FromMetadata)
+	}
+	return value, nil
+}
+
+// GCPAssign sets a literal or metadata-derived value at `location` within resource.data
+// for every matching asset.
+type GCPAssign struct {
+	id       string
+	applyTo  []string
+	location string
+	value    assignValue
+	match    Match
+}
+
+// ID implements Mutator.
+func (a *GCPAssign) ID() string { return a.id }
+
+// Matches implements Mutator.
+func (a *GCPAssign) Matches(asset map[string]interface{}) (bool, error) {
+	assetType, _ := asset["asset_type"].(string)
+	return stringInSlice(a.applyTo, assetType) && a.match.allows(asset), nil
+}
+
+// Patch implements Mutator.
+func (a *GCPAssign) Patch(asset map[string]interface{}) ([]JSONPatch, error) {
+	pointer, err := locationToPointer(a.location)
+	if err != nil {
+		return nil, err
+	}
+	value, err := a.value.resolve(asset)
+	if err != nil {
+		return nil, fmt.Errorf("mutator %s: %w", a.id, err)
+	}
+	return []JSONPatch{{Op: "add", Path: pointer, Value: value}}, nil
+}
+
+// GCPAssignMetadata sets a literal or metadata-derived value onto the asset's own
+// top-level metadata (ancestry_path, labels, ...) rather than resource.data.
+type GCPAssignMetadata struct {
+	id       string
+	applyTo  []string
+	location string
+	value    assignValue
+	match    Match
+}
+
+// ID implements Mutator.
+func (a *GCPAssignMetadata) ID() string { return a.id }
+
+// Matches implements Mutator.
+func (a *GCPAssignMetadata) Matches(asset map[string]interface{}) (bool, error) {
+	assetType, _ := asset["asset_type"].(string)
+	return stringInSlice(a.applyTo, assetType) && a.match.allows(asset), nil
+}
+
+// Patch implements Mutator.
+func (a *GCPAssignMetadata) Patch(asset map[string]interface{}) ([]JSONPatch, error) {
+	value, err := a.value.resolve(asset)
+	if err != nil {
+		return nil, fmt.Errorf("mutator %s: %w", a.id, err)
+	}
+	pointer := "/" + strings.ReplaceAll(strings.TrimPrefix(a.location, "."), ".", "/")
+	return []JSONPatch{{Op: "add", Path: pointer, Value: value}}, nil
+}
+
+func stringInSlice(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// FromUnstructured converts the GCPAssign/GCPAssignMetadata documents in objs (loaded
+// alongside constraints and constraint templates from policyPaths) into Mutators. Any
+// document whose kind isn't one of these is skipped.
+func FromUnstructured(objs []*unstructured.Unstructured) ([]Mutator, error) {
+	var mutators []Mutator
+	for _, u := range objs {
+		switch u.GetKind() {
+		case GCPAssignKind:
+			m, err := parseAssign(u)
+			if err != nil {
+				return nil, fmt.Errorf("parsing GCPAssign %s: %w", u.GetName(), err)
+			}
+			mutators = append(mutators, m)
+		case GCPAssignMetadataKind:
+			m, err := parseAssignMetadata(u)
+			if err != nil {
+				return nil, fmt.Errorf("parsing GCPAssignMetadata %s: %w", u.GetName(), err)
+			}
+			mutators = append(mutators, m)
+		}
+	}
+	return mutators, nil
+}
+
+func parseCommon(u *unstructured.Unstructured) (applyTo []string, location string, value assignValue, match Match, err error) {
+	spec, found, err := unstructured.NestedMap(u.Object, "spec")
+	if err != nil || !found {
+		return nil, "", assignValue{}, Match{}, fmt.Errorf("missing or invalid spec")
+	}
+
+	applyTo, _, err = unstructured.NestedStringSlice(spec, "applyTo")
+	if err != nil || len(applyTo) == 0 {
+		return nil, "", assignValue{}, Match{}, fmt.Errorf("spec.applyTo must be set")
+	}
+
+	location, _, err = unstructured.NestedString(spec, "location")
+	if err != nil || location == "" {
+		return nil, "", assignValue{}, Match{}, fmt.Errorf("spec.location must be set")
+	}
+
+	literal, literalFound, _ := unstructured.NestedFieldNoCopy(spec, "parameters", "assign", "value")
+	fromMetadata, _, _ := unstructured.NestedString(spec, "parameters", "assign", "fromMetadata")
+	if !literalFound && fromMetadata == "" {
+		return nil, "", assignValue{}, Match{}, fmt.Errorf("spec.parameters.assign must set value or fromMetadata")
+	}
+	value = assignValue{Literal: literal, FromMetadata: fromMetadata}
+
+	ancestries, _, _ := unstructured.NestedStringSlice(spec, "match", "ancestries")
+	match = Match{Ancestries: ancestries}
+
+	return applyTo, location, value, match, nil
+}
+
+func parseAssign(u *unstructured.Unstructured) (*GCPAssign, error) {
+	applyTo, location, value, match, err := parseCommon(u)
+	if err != nil {
+		return nil, err
+	}
+	return &GCPAssign{id: u.GetName(), applyTo: applyTo, location: location, value: value, match: match}, nil
+}
+
+func parseAssignMetadata(u *unstructured.Unstructured) (*GCPAssignMetadata, error) {
+	applyTo, location, value, match, err := parseCommon(u)
+	if err != nil {
+		return nil, err
+	}
+	return &GCPAssignMetadata{id: u.GetName(), applyTo: applyTo, location: location, value: value, match: match}, nil
+}
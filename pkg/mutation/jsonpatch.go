@@ -0,0 +1,79 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// locationToPointer converts a mutator spec's `location`, a dot-separated JSONPath into
+// resource.data (e.g. ".properties.networkInterfaces.network"), into an RFC 6902 JSON
+// Pointer rooted at the asset (e.g. "/resource/data/properties/networkInterfaces/network").
+func locationToPointer(location string) (string, error) {
+	location = strings.TrimPrefix(location, ".")
+	if location == "" {
+		return "", fmt.Errorf("location must not be empty")
+	}
+	parts := strings.Split(location, ".")
+	return "/resource/data/" + strings.Join(parts, "/"), nil
+}
+
+// splitJSONPointer splits an RFC 6902 JSON Pointer into its unescaped reference tokens.
+func splitJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" || pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q: must start with '/'", pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, "~1", "/")
+		p = strings.ReplaceAll(p, "~0", "~")
+		parts[i] = p
+	}
+	return parts, nil
+}
+
+// setAtPath sets value at path within obj, creating intermediate maps as needed.
+func setAtPath(obj map[string]interface{}, path []string, value interface{}) error {
+	cur := obj
+	for i, key := range path[:len(path)-1] {
+		next, ok := cur[key]
+		if !ok {
+			next = map[string]interface{}{}
+			cur[key] = next
+		}
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot descend into %q: not an object", strings.Join(path[:i+1], "/"))
+		}
+		cur = nextMap
+	}
+	cur[path[len(path)-1]] = value
+	return nil
+}
+
+// removeAtPath deletes the value at path within obj.
+func removeAtPath(obj map[string]interface{}, path []string) error {
+	cur := obj
+	for i, key := range path[:len(path)-1] {
+		next, ok := cur[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot descend into %q: not an object", strings.Join(path[:i+1], "/"))
+		}
+		cur = next
+	}
+	delete(cur, path[len(path)-1])
+	return nil
+}
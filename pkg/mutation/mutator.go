@@ -0,0 +1,81 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mutation implements a small, Gatekeeper-inspired mutation framework for
+// suggesting fixes to GCP assets: Assign and AssignMetadata mutators compute RFC 6902
+// JSON Patches that would bring an asset into compliance with a constraint, without ever
+// mutating the caller's input. Callers (terraform-validator, CI bots, ...) apply the
+// patches themselves if they choose to.
+package mutation
+
+import "fmt"
+
+// JSONPatch is a single RFC 6902 JSON Patch operation.
+type JSONPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Mutator computes the patches needed to bring an asset into compliance with whatever
+// rule it encodes.
+type Mutator interface {
+	// ID identifies this mutator; it's how a constraint's `remediation:` stanza refers
+	// to it.
+	ID() string
+	// Matches reports whether this mutator applies to asset.
+	Matches(asset map[string]interface{}) (bool, error)
+	// Patch returns the JSON Patch operations that would apply this mutator's change to
+	// asset. It must not modify asset.
+	Patch(asset map[string]interface{}) ([]JSONPatch, error)
+}
+
+// ApplyPatches returns a copy of asset with patches applied in order. asset is not
+// modified. Applying the same patches to the result again is a no-op (idempotent),
+// since each patch's value is computed directly from the mutator's assign value, not
+// derived from the asset's prior value.
+func ApplyPatches(asset map[string]interface{}, patches []JSONPatch) (map[string]interface{}, error) {
+	result := deepCopyMap(asset)
+	for _, patch := range patches {
+		path, err := splitJSONPointer(patch.Path)
+		if err != nil {
+			return nil, fmt.Errorf("patch %+v: %w", patch, err)
+		}
+		switch patch.Op {
+		case "add", "replace":
+			if err := setAtPath(result, path, patch.Value); err != nil {
+				return nil, fmt.Errorf("applying patch %+v: %w", patch, err)
+			}
+		case "remove":
+			if err := removeAtPath(result, path); err != nil {
+				return nil, fmt.Errorf("applying patch %+v: %w", patch, err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported patch op %q", patch.Op)
+		}
+	}
+	return result, nil
+}
+
+func deepCopyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			out[k] = deepCopyMap(nested)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
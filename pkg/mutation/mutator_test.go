@@ -0,0 +1,136 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestAsset() map[string]interface{} {
+	return map[string]interface{}{
+		"name":       "//compute.googleapis.com/projects/p/zones/z/instances/i",
+		"asset_type": "compute.googleapis.com/Instance",
+		"resource": map[string]interface{}{
+			"data": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"disks": []interface{}{},
+				},
+			},
+		},
+	}
+}
+
+func TestGCPAssign_PatchAndApply(t *testing.T) {
+	m := &GCPAssign{
+		id:       "require-encryption",
+		applyTo:  []string{"compute.googleapis.com/Instance"},
+		location: ".properties.encryption",
+		value:    assignValue{Literal: "CUSTOMER_SUPPLIED"},
+	}
+
+	asset := newTestAsset()
+	matched, err := m.Matches(asset)
+	if err != nil || !matched {
+		t.Fatalf("expected mutator to match, matched=%v err=%v", matched, err)
+	}
+
+	patches, err := m.Patch(asset)
+	if err != nil {
+		t.Fatalf("Patch returned error: %v", err)
+	}
+
+	fixed, err := ApplyPatches(asset, patches)
+	if err != nil {
+		t.Fatalf("ApplyPatches returned error: %v", err)
+	}
+	props := fixed["resource"].(map[string]interface{})["data"].(map[string]interface{})["properties"].(map[string]interface{})
+	if props["encryption"] != "CUSTOMER_SUPPLIED" {
+		t.Errorf("expected encryption to be set, got %v", props["encryption"])
+	}
+
+	// original asset must be untouched.
+	origProps := asset["resource"].(map[string]interface{})["data"].(map[string]interface{})["properties"].(map[string]interface{})
+	if _, ok := origProps["encryption"]; ok {
+		t.Errorf("ApplyPatches must not mutate the input asset")
+	}
+}
+
+func TestApplyPatches_Idempotent(t *testing.T) {
+	m := &GCPAssign{
+		id:       "require-encryption",
+		applyTo:  []string{"compute.googleapis.com/Instance"},
+		location: ".properties.encryption",
+		value:    assignValue{Literal: "CUSTOMER_SUPPLIED"},
+	}
+	asset := newTestAsset()
+	patches, err := m.Patch(asset)
+	if err != nil {
+		t.Fatalf("Patch returned error: %v", err)
+	}
+
+	once, err := ApplyPatches(asset, patches)
+	if err != nil {
+		t.Fatalf("ApplyPatches returned error: %v", err)
+	}
+	twice, err := ApplyPatches(once, patches)
+	if err != nil {
+		t.Fatalf("ApplyPatches returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(once, twice) {
+		t.Errorf("re-applying the same patches should be a no-op, got %v vs %v", once, twice)
+	}
+}
+
+func TestGCPAssign_NoMatch(t *testing.T) {
+	m := &GCPAssign{
+		id:       "irrelevant",
+		applyTo:  []string{"storage.googleapis.com/Bucket"},
+		location: ".encryption",
+		value:    assignValue{Literal: "x"},
+	}
+	matched, err := m.Matches(newTestAsset())
+	if err != nil {
+		t.Fatalf("Matches returned error: %v", err)
+	}
+	if matched {
+		t.Errorf("expected no match for unrelated asset type")
+	}
+}
+
+func TestMutatorOrdering_LastWriteWins(t *testing.T) {
+	first := &GCPAssign{applyTo: []string{"compute.googleapis.com/Instance"}, location: ".properties.disks", value: assignValue{Literal: "first"}}
+	second := &GCPAssign{applyTo: []string{"compute.googleapis.com/Instance"}, location: ".properties.disks", value: assignValue{Literal: "second"}}
+
+	asset := newTestAsset()
+	var patches []JSONPatch
+	for _, m := range []*GCPAssign{first, second} {
+		p, err := m.Patch(asset)
+		if err != nil {
+			t.Fatalf("Patch returned error: %v", err)
+		}
+		patches = append(patches, p...)
+	}
+
+	fixed, err := ApplyPatches(asset, patches)
+	if err != nil {
+		t.Fatalf("ApplyPatches returned error: %v", err)
+	}
+	props := fixed["resource"].(map[string]interface{})["data"].(map[string]interface{})["properties"].(map[string]interface{})
+	if props["disks"] != "second" {
+		t.Errorf("expected later mutator in the list to win, got %v", props["disks"])
+	}
+}